@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+)
+
+// fakeFetcher serves pre-baked asset bodies by name, for exercising the
+// checksum/signature download paths without a network.
+type fakeFetcher struct {
+	bodies map[string]string
+}
+
+func (f *fakeFetcher) LatestRelease(ctx context.Context, current string) (Release, bool, error) {
+	return Release{}, false, nil
+}
+
+func (f *fakeFetcher) Download(ctx context.Context, asset Asset) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(f.bodies[asset.Name])), nil
+}
+
+func TestFetchExpectedChecksum(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		filename string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "matching entry",
+			manifest: "deadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef  myapp_linux_amd64.tar.gz\n",
+			filename: "myapp_linux_amd64.tar.gz",
+			want:     "deadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef",
+		},
+		{
+			name:     "no entry for filename",
+			manifest: "deadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef  other.tar.gz\n",
+			filename: "myapp_linux_amd64.tar.gz",
+			wantErr:  true,
+		},
+		{
+			name:     "malformed line is ignored",
+			manifest: "not a checksum line\ndeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef  myapp_linux_amd64.tar.gz\n",
+			filename: "myapp_linux_amd64.tar.gz",
+			want:     "deadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcher := &fakeFetcher{bodies: map[string]string{"checksums.txt": tt.manifest}}
+			assets := []Asset{{Name: "checksums.txt"}}
+			info := newInfos("owner", "myapp", "v1.0.0")
+
+			got, err := fetchExpectedChecksum(context.Background(), fetcher, assets, "v1.0.0", info, tt.filename)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got none")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchExpectedChecksumNoManifest(t *testing.T) {
+	fetcher := &fakeFetcher{bodies: map[string]string{}}
+	info := newInfos("owner", "myapp", "v1.0.0")
+
+	got, err := fetchExpectedChecksum(context.Background(), fetcher, nil, "v1.0.0", info, "myapp_linux_amd64.tar.gz")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got != "" {
+		t.Fatalf("expected empty digest when no checksums asset is published, got %q", got)
+	}
+}
+
+func TestVerifyDigest(t *testing.T) {
+	hasher := sha256.New()
+	hasher.Write([]byte("payload"))
+	expected := hex.EncodeToString(hasher.Sum(nil))
+
+	if err := verifyDigest(sha256Hasher(), expected); err == nil {
+		t.Fatalf("expected mismatch against an empty hasher")
+	}
+
+	hasher = sha256.New()
+	hasher.Write([]byte("payload"))
+
+	if err := verifyDigest(hasher, expected); err != nil {
+		t.Fatalf("unexpected error for matching digest: %v", err)
+	}
+}
+
+func TestDecodeSignature(t *testing.T) {
+	raw := make([]byte, ed25519.SignatureSize)
+	for i := range raw {
+		raw[i] = byte(i)
+	}
+
+	tests := []struct {
+		name string
+		in   []byte
+	}{
+		{name: "raw bytes", in: raw},
+		{name: "hex", in: []byte(hex.EncodeToString(raw))},
+		{name: "hex with trailing newline", in: []byte(hex.EncodeToString(raw) + "\n")},
+		{name: "standard base64", in: []byte(base64.StdEncoding.EncodeToString(raw))},
+		{name: "unpadded base64", in: []byte(base64.RawStdEncoding.EncodeToString(raw))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := decodeSignature(tt.in)
+			if string(got) != string(raw) {
+				t.Fatalf("decodeSignature(%q) = %x, want %x", tt.in, got, raw)
+			}
+		})
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+
+	payload := []byte("release-bytes")
+	signature := ed25519.Sign(private, payload)
+
+	tests := []struct {
+		name    string
+		sigBody string
+		wantErr bool
+	}{
+		{name: "hex-encoded signature verifies", sigBody: hex.EncodeToString(signature)},
+		{name: "base64-encoded signature verifies", sigBody: base64.StdEncoding.EncodeToString(signature)},
+		{name: "raw signature verifies", sigBody: string(signature)},
+		{name: "wrong signature is rejected", sigBody: hex.EncodeToString(ed25519.Sign(private, []byte("other-bytes"))), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcher := &fakeFetcher{bodies: map[string]string{"myapp.sig": tt.sigBody}}
+
+			err := verifySignature(context.Background(), fetcher, Asset{Name: "myapp.sig"}, public, payload)
+			if tt.wantErr && err == nil {
+				t.Fatalf("expected signature verification to fail")
+			}
+
+			if !tt.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
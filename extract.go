@@ -0,0 +1,191 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/ulikunitz/xz"
+)
+
+// ExtractError is an error produced while locating or decompressing the
+// binary inside a downloaded release asset.
+type ExtractError struct {
+	Message string
+}
+
+func (e ExtractError) Error() string {
+	return fmt.Sprintf("extract error: %s", e.Message)
+}
+
+// maxExtractedSize bounds how much decompressed data extractBinary will
+// ever hold in memory for a single entry, as a defence against zip/gzip
+// bombs hidden in a malicious or corrupted release asset.
+const maxExtractedSize = 500 * 1024 * 1024 // 500MB
+
+// binaryName is the filename we look for inside archives: appName, with
+// a ".exe" suffix on Windows.
+func binaryName(appName string) string {
+	if runtime.GOOS == "windows" {
+		return appName + ".exe"
+	}
+
+	return appName
+}
+
+// extractBinary sniffs r's magic bytes to detect .tar.gz, .tar.xz,
+// .tar.bz2, .zip, .gz, or an uncompressed binary, and returns a reader
+// over the appName entry (the whole payload, for single-file formats).
+func extractBinary(r io.Reader, appName string) (io.Reader, error) {
+	payload, err := readWithLimit(r, maxExtractedSize)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case isZip(payload):
+		return extractFromZip(payload, appName)
+	case isGzip(payload):
+		return extractFromGzip(payload, appName)
+	case isBzip2(payload):
+		return extractFromTar(bzip2.NewReader(bytes.NewReader(payload)), appName)
+	case isXz(payload):
+		xzReader, err := xz.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, ExtractError{Message: err.Error()}
+		}
+
+		return extractFromTar(xzReader, appName)
+	default:
+		return bytes.NewReader(payload), nil
+	}
+}
+
+// readWithLimit reads all of r, rejecting input larger than limit instead
+// of silently truncating it.
+func readWithLimit(r io.Reader, limit int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, ExtractError{Message: err.Error()}
+	}
+
+	if int64(len(data)) > limit {
+		return nil, ExtractError{Message: "asset exceeds maximum allowed decompressed size"}
+	}
+
+	return data, nil
+}
+
+func isZip(b []byte) bool {
+	return len(b) >= 4 && b[0] == 'P' && b[1] == 'K' && (b[2] == 3 || b[2] == 5) && (b[3] == 4 || b[3] == 6)
+}
+
+func isGzip(b []byte) bool {
+	return len(b) >= 2 && b[0] == 0x1f && b[1] == 0x8b
+}
+
+func isBzip2(b []byte) bool {
+	return len(b) >= 3 && b[0] == 'B' && b[1] == 'Z' && b[2] == 'h'
+}
+
+func isXz(b []byte) bool {
+	return len(b) >= 6 && bytes.Equal(b[:6], []byte{0xfd, '7', 'z', 'X', 'Z', 0x00})
+}
+
+// isTar reports whether b carries the POSIX ustar magic at its expected
+// offset, distinguishing a tar archive from a single gzip-compressed file.
+func isTar(b []byte) bool {
+	return len(b) > 262 && string(b[257:262]) == "ustar"
+}
+
+func extractFromGzip(payload []byte, appName string) (io.Reader, error) {
+	gzipReader, err := gzip.NewReader(bytes.NewReader(payload))
+	if err != nil {
+		return nil, ExtractError{Message: err.Error()}
+	}
+	defer gzipReader.Close()
+
+	decompressed, err := readWithLimit(gzipReader, maxExtractedSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if isTar(decompressed) {
+		return extractFromTar(bytes.NewReader(decompressed), appName)
+	}
+
+	return bytes.NewReader(decompressed), nil
+}
+
+func extractFromTar(r io.Reader, appName string) (io.Reader, error) {
+	name := binaryName(appName)
+	tarReader := tar.NewReader(r)
+
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, ExtractError{Message: err.Error()}
+		}
+
+		if header.Typeflag != tar.TypeReg || !strings.EqualFold(filepath.Base(header.Name), name) {
+			continue
+		}
+
+		data, err := readWithLimit(tarReader, maxExtractedSize)
+		if err != nil {
+			return nil, err
+		}
+
+		return bytes.NewReader(data), nil
+	}
+
+	return nil, ExtractError{Message: fmt.Sprintf("no entry named %s found in archive", name)}
+}
+
+func extractFromZip(payload []byte, appName string) (io.Reader, error) {
+	zipReader, err := zip.NewReader(bytes.NewReader(payload), int64(len(payload)))
+	if err != nil {
+		return nil, ExtractError{Message: err.Error()}
+	}
+
+	name := binaryName(appName)
+
+	for _, zipFile := range zipReader.File {
+		if zipFile.FileInfo().IsDir() || zipFile.Mode()&os.ModeSymlink != 0 {
+			continue
+		}
+
+		if !strings.EqualFold(filepath.Base(zipFile.Name), name) {
+			continue
+		}
+
+		reader, err := zipFile.Open()
+		if err != nil {
+			return nil, ExtractError{Message: err.Error()}
+		}
+
+		defer reader.Close()
+
+		data, err := readWithLimit(reader, maxExtractedSize)
+		if err != nil {
+			return nil, err
+		}
+
+		return bytes.NewReader(data), nil
+	}
+
+	return nil, ExtractError{Message: fmt.Sprintf("no entry named %s found in archive", name)}
+}
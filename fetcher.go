@@ -0,0 +1,344 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Asset is a single downloadable file attached to a Release, e.g. a
+// platform-specific tarball, a checksums.txt, or a detached signature.
+type Asset struct {
+	// Name is the asset's filename, as used for matching against
+	// checksums.txt entries and signature sibling files.
+	Name string
+
+	// URL is whatever locator the originating Fetcher needs to retrieve
+	// the asset's contents via Fetcher.Download. Its shape is
+	// fetcher-specific (an HTTPS URL, an s3:// URI, ...).
+	URL string
+}
+
+// Release describes a single published version of the application.
+type Release struct {
+	TagName    string
+	Prerelease bool
+	Assets     []Asset
+}
+
+// Fetcher abstracts where release metadata and assets come from, so Update
+// isn't hardwired to GitHub.
+type Fetcher interface {
+	// LatestRelease returns the newest release this Fetcher knows about,
+	// whether it is newer than current, and any error encountered.
+	LatestRelease(ctx context.Context, current string) (Release, bool, error)
+
+	// Download opens a reader over asset's contents. Callers must close
+	// the returned ReadCloser.
+	Download(ctx context.Context, asset Asset) (io.ReadCloser, error)
+}
+
+// Pinnable is implemented by Fetchers that support Update.Pin and
+// Update.AllowDowngrade. Update.fetcher applies both to whatever Fetcher
+// it ends up using - the default GitHubFetcher or one supplied via
+// Update.Fetcher - whenever it implements this interface, so the two
+// settings aren't silently limited to the auto-constructed default.
+// GitHubFetcher, GitLabFetcher, S3Fetcher and HTTPFetcher all implement
+// it; a custom Fetcher can opt in the same way.
+type Pinnable interface {
+	SetPin(pin string)
+	SetAllowDowngrade(allow bool)
+}
+
+// httpDownload performs a plain GET against url. GitHubFetcher,
+// GitLabFetcher, S3Fetcher and HTTPFetcher all resolve their assets to a
+// plain HTTPS URL, so they share this implementation.
+func httpDownload(ctx context.Context, url string) (io.ReadCloser, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, DownloadError{Message: err.Error()}
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, DownloadError{Message: err.Error()}
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		response.Body.Close()
+
+		return nil, DownloadError{Message: fmt.Sprintf("unexpected status %s for %s", response.Status, url)}
+	}
+
+	return response.Body, nil
+}
+
+// GitHubFetcher retrieves releases from a GitHub repository's Releases
+// API, picking the newest one that matches Channel (and, if Pin is set,
+// that exact tag instead).
+type GitHubFetcher struct {
+	Owner string
+	Repo  string
+
+	// Channel restricts which releases are considered; zero value is
+	// ChannelStable.
+	Channel Channel
+
+	// Pin, if set, locks LatestRelease to this exact tag regardless of
+	// Channel - useful to roll back to, or stay on, a known-good release.
+	Pin string
+
+	// AllowDowngrade lets LatestRelease report an update even when the
+	// selected release is older than current, e.g. to honor Pin as a
+	// rollback.
+	AllowDowngrade bool
+}
+
+// NewGitHubFetcher builds a GitHubFetcher for owner/repo on the stable
+// channel.
+func NewGitHubFetcher(owner, repo string) *GitHubFetcher {
+	return &GitHubFetcher{Owner: owner, Repo: repo, Channel: ChannelStable}
+}
+
+func (f *GitHubFetcher) SetPin(pin string) { f.Pin = pin }
+
+func (f *GitHubFetcher) SetAllowDowngrade(allow bool) { f.AllowDowngrade = allow }
+
+// githubRelease is the subset of GitHub's release API response we need.
+type githubRelease struct {
+	TagName    string `json:"tag_name"`
+	Prerelease bool   `json:"prerelease"`
+	Assets     []struct {
+		Name               string `json:"name"`
+		BrowserDownloadURL string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+func (f *GitHubFetcher) LatestRelease(ctx context.Context, current string) (Release, bool, error) {
+	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases", f.Owner, f.Repo)
+
+	body, err := httpDownload(ctx, url)
+	if err != nil {
+		return Release{}, false, err
+	}
+
+	defer body.Close()
+
+	var releases []githubRelease
+
+	if err := json.NewDecoder(body).Decode(&releases); err != nil {
+		return Release{}, false, DownloadError{Message: err.Error()}
+	}
+
+	selected, found := pickRelease(releases, f.Channel, f.Pin)
+	if !found {
+		return Release{}, false, nil
+	}
+
+	assets := make([]Asset, 0, len(selected.Assets))
+	for _, asset := range selected.Assets {
+		assets = append(assets, Asset{Name: asset.Name, URL: asset.BrowserDownloadURL})
+	}
+
+	release := Release{TagName: selected.TagName, Prerelease: selected.Prerelease, Assets: assets}
+
+	return release, versionNewer(selected.TagName, current, f.AllowDowngrade), nil
+}
+
+func (f *GitHubFetcher) Download(ctx context.Context, asset Asset) (io.ReadCloser, error) {
+	return httpDownload(ctx, asset.URL)
+}
+
+// GitLabFetcher retrieves releases from a GitLab project's Releases API.
+type GitLabFetcher struct {
+	// BaseURL is the GitLab instance, e.g. "https://gitlab.com".
+	BaseURL string
+
+	// ProjectID is the numeric or URL-encoded path/namespace ID.
+	ProjectID string
+
+	// Token is an optional private token for self-hosted/private projects.
+	Token string
+
+	// Pin, if set, only reports an update when the project's latest
+	// release happens to carry this tag - GitLab's permalink/latest
+	// endpoint exposes no release history to search. See resolveUpdate.
+	Pin string
+
+	// AllowDowngrade lets LatestRelease report an update even when the
+	// latest release is older than current.
+	AllowDowngrade bool
+}
+
+func (f *GitLabFetcher) SetPin(pin string) { f.Pin = pin }
+
+func (f *GitLabFetcher) SetAllowDowngrade(allow bool) { f.AllowDowngrade = allow }
+
+type gitlabRelease struct {
+	TagName string `json:"tag_name"`
+	Assets  struct {
+		Links []struct {
+			Name           string `json:"name"`
+			DirectAssetURL string `json:"direct_asset_url"`
+		} `json:"links"`
+	} `json:"assets"`
+}
+
+func (f *GitLabFetcher) LatestRelease(ctx context.Context, current string) (Release, bool, error) {
+	url := fmt.Sprintf("%s/api/v4/projects/%s/releases/permalink/latest", strings.TrimRight(f.BaseURL, "/"), f.ProjectID)
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Release{}, false, DownloadError{Message: err.Error()}
+	}
+
+	if f.Token != "" {
+		request.Header.Set("PRIVATE-TOKEN", f.Token)
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return Release{}, false, DownloadError{Message: err.Error()}
+	}
+
+	defer response.Body.Close()
+
+	var release gitlabRelease
+
+	if err := json.NewDecoder(response.Body).Decode(&release); err != nil {
+		return Release{}, false, DownloadError{Message: err.Error()}
+	}
+
+	assets := make([]Asset, 0, len(release.Assets.Links))
+	for _, link := range release.Assets.Links {
+		assets = append(assets, Asset{Name: link.Name, URL: link.DirectAssetURL})
+	}
+
+	hasUpdate := resolveUpdate(release.TagName, current, f.Pin, f.AllowDowngrade)
+
+	return Release{TagName: release.TagName, Assets: assets}, hasUpdate, nil
+}
+
+func (f *GitLabFetcher) Download(ctx context.Context, asset Asset) (io.ReadCloser, error) {
+	return httpDownload(ctx, asset.URL)
+}
+
+// S3Fetcher retrieves releases from objects stored under a prefix in an S3
+// (or S3-compatible) bucket, alongside a "latest.json" manifest listing the
+// current version and its asset keys.
+type S3Fetcher struct {
+	// Endpoint is the bucket's public HTTPS base URL, e.g.
+	// "https://my-bucket.s3.amazonaws.com".
+	Endpoint string
+
+	// Prefix is prepended to object keys, e.g. "releases/myapp/".
+	Prefix string
+
+	// Pin, if set, only reports an update when latest.json's version
+	// happens to match this tag - there's no release history to search
+	// behind a single manifest. See resolveUpdate.
+	Pin string
+
+	// AllowDowngrade lets LatestRelease report an update even when the
+	// manifest's version is older than current.
+	AllowDowngrade bool
+}
+
+func (f *S3Fetcher) SetPin(pin string) { f.Pin = pin }
+
+func (f *S3Fetcher) SetAllowDowngrade(allow bool) { f.AllowDowngrade = allow }
+
+type s3Manifest struct {
+	Version string   `json:"version"`
+	Assets  []string `json:"assets"`
+}
+
+func (f *S3Fetcher) manifestURL() string {
+	return strings.TrimRight(f.Endpoint, "/") + "/" + f.Prefix + "latest.json"
+}
+
+func (f *S3Fetcher) assetURL(key string) string {
+	return strings.TrimRight(f.Endpoint, "/") + "/" + f.Prefix + key
+}
+
+func (f *S3Fetcher) LatestRelease(ctx context.Context, current string) (Release, bool, error) {
+	body, err := httpDownload(ctx, f.manifestURL())
+	if err != nil {
+		return Release{}, false, err
+	}
+
+	defer body.Close()
+
+	var manifest s3Manifest
+
+	if err := json.NewDecoder(body).Decode(&manifest); err != nil {
+		return Release{}, false, DownloadError{Message: err.Error()}
+	}
+
+	assets := make([]Asset, 0, len(manifest.Assets))
+	for _, key := range manifest.Assets {
+		assets = append(assets, Asset{Name: key, URL: f.assetURL(key)})
+	}
+
+	hasUpdate := resolveUpdate(manifest.Version, current, f.Pin, f.AllowDowngrade)
+
+	return Release{TagName: manifest.Version, Assets: assets}, hasUpdate, nil
+}
+
+func (f *S3Fetcher) Download(ctx context.Context, asset Asset) (io.ReadCloser, error) {
+	return httpDownload(ctx, asset.URL)
+}
+
+// HTTPFetcher retrieves releases from a plain JSON manifest served at a
+// fixed URL, for self-hosted deployments that don't have a proper release
+// API. The manifest has the same shape as S3Fetcher's "latest.json", with
+// asset URLs resolved relative to ManifestURL.
+type HTTPFetcher struct {
+	ManifestURL string
+
+	// Pin, if set, only reports an update when the manifest's version
+	// happens to match this tag - there's no release history to search
+	// behind a single manifest. See resolveUpdate.
+	Pin string
+
+	// AllowDowngrade lets LatestRelease report an update even when the
+	// manifest's version is older than current.
+	AllowDowngrade bool
+}
+
+func (f *HTTPFetcher) SetPin(pin string) { f.Pin = pin }
+
+func (f *HTTPFetcher) SetAllowDowngrade(allow bool) { f.AllowDowngrade = allow }
+
+func (f *HTTPFetcher) LatestRelease(ctx context.Context, current string) (Release, bool, error) {
+	body, err := httpDownload(ctx, f.ManifestURL)
+	if err != nil {
+		return Release{}, false, err
+	}
+
+	defer body.Close()
+
+	var manifest s3Manifest
+
+	if err := json.NewDecoder(body).Decode(&manifest); err != nil {
+		return Release{}, false, DownloadError{Message: err.Error()}
+	}
+
+	base := f.ManifestURL[:strings.LastIndex(f.ManifestURL, "/")+1]
+
+	assets := make([]Asset, 0, len(manifest.Assets))
+	for _, key := range manifest.Assets {
+		assets = append(assets, Asset{Name: key, URL: base + key})
+	}
+
+	hasUpdate := resolveUpdate(manifest.Version, current, f.Pin, f.AllowDowngrade)
+
+	return Release{TagName: manifest.Version, Assets: assets}, hasUpdate, nil
+}
+
+func (f *HTTPFetcher) Download(ctx context.Context, asset Asset) (io.ReadCloser, error) {
+	return httpDownload(ctx, asset.URL)
+}
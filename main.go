@@ -3,18 +3,18 @@ package main
 // Logic adapted from: https://github.com/yitsushi/totp-cli/blob/main/internal/cmd/update.go
 
 import (
-	"archive/tar"
-	"compress/gzip"
+	"bytes"
 	"context"
+	"crypto/ed25519"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
 	"path"
 	"runtime"
+	"strings"
+	"time"
 
-	"github.com/kardianos/osext"                   // Find the current Executable and ExecutableFolder.
-	grc "github.com/yitsushi/github-release-check" // Check Github repo version
+	"github.com/kardianos/osext" // Find the current Executable and ExecutableFolder.
 	// manage commands for your CLI tool
 )
 
@@ -22,6 +22,10 @@ type infos struct {
 	AppRepoOwner string
 	AppName      string
 	AppVersion   string
+
+	// Channel restricts update checks to a class of GitHub releases
+	// (stable, beta, nightly); the zero value means ChannelStable.
+	Channel Channel
 }
 
 func newInfos(owner string, name string, ver string) *infos {
@@ -33,7 +37,79 @@ func newInfos(owner string, name string, ver string) *infos {
 }
 
 // Update structure is the representation of the update command.
-type Update struct{}
+type Update struct {
+	// Fetcher locates and downloads releases. Defaults to a GitHubFetcher
+	// built from infos.AppRepoOwner/AppName if left nil.
+	Fetcher Fetcher
+
+	// AssetMatcher selects the release asset to install. Defaults to
+	// matching an asset whose name contains both runtime.GOOS and
+	// runtime.GOARCH.
+	AssetMatcher func(name string) bool
+
+	// RequireChecksums aborts the update if no checksums.txt asset can be
+	// found on the release, instead of silently installing an unverified
+	// binary.
+	RequireChecksums bool
+
+	// ExpectedSigner, when set, requires a detached raw-ed25519 signature
+	// asset (<asset>.sig) that verifies against this key. A release that
+	// doesn't publish one is rejected, not silently installed unsigned.
+	ExpectedSigner ed25519.PublicKey
+
+	// Restart re-executes the freshly installed binary once the update
+	// completes, instead of leaving the old process running.
+	Restart bool
+
+	// CheckInterval bounds how often PromptForUpdate is willing to hit
+	// the network between calls. Zero means defaultCheckInterval.
+	CheckInterval time.Duration
+
+	// Pin locks updates to a specific tag (e.g. "v1.2.3") instead of the
+	// newest release on info.Channel - useful to roll back a bad release.
+	// Applied to whichever Fetcher is in use (default or c.Fetcher) when
+	// it implements Pinnable; all four built-in fetchers do.
+	Pin string
+
+	// AllowDowngrade permits installing Pin (or an otherwise-selected
+	// release) even when it's older than the running version. Same
+	// Pinnable caveat as Pin.
+	AllowDowngrade bool
+}
+
+// defaultAssetMatcher matches an asset whose filename contains both the
+// current GOOS and GOARCH, e.g. "myapp-linux-amd64.tar.gz".
+func defaultAssetMatcher(name string) bool {
+	return strings.Contains(name, runtime.GOOS) && strings.Contains(name, runtime.GOARCH)
+}
+
+// fetcher returns c.Fetcher, or a GitHubFetcher for info's repo if unset,
+// applying c.Pin/c.AllowDowngrade to it when it implements Pinnable.
+func (c *Update) fetcher(info *infos) Fetcher {
+	fetcher := c.Fetcher
+
+	if fetcher == nil {
+		githubFetcher := NewGitHubFetcher(info.AppRepoOwner, info.AppName)
+		githubFetcher.Channel = info.Channel
+		fetcher = githubFetcher
+	}
+
+	if pinnable, ok := fetcher.(Pinnable); ok {
+		pinnable.SetPin(c.Pin)
+		pinnable.SetAllowDowngrade(c.AllowDowngrade)
+	}
+
+	return fetcher
+}
+
+// assetMatcher returns c.AssetMatcher, or defaultAssetMatcher if unset.
+func (c *Update) assetMatcher() func(name string) bool {
+	if c.AssetMatcher != nil {
+		return c.AssetMatcher
+	}
+
+	return defaultAssetMatcher
+}
 
 const (
 	binaryChmodValue = 0o755
@@ -77,7 +153,20 @@ func (e DeleteError) Error() string {
 
 // Execute is the main function. It will be called on update command.
 func (c *Update) Execute(info *infos) {
-	hasUpdate, release, _ := grc.Check(info.AppRepoOwner, info.AppName, info.AppVersion)
+	ctx := context.Background()
+
+	if currentExecutable, err := osext.Executable(); err == nil {
+		cleanupStaleReplace(currentExecutable)
+	}
+
+	fetcher := c.fetcher(info)
+
+	release, hasUpdate, err := fetcher.LatestRelease(ctx, info.AppVersion)
+	if err != nil {
+		fmt.Printf("Error: %s\n", err.Error())
+
+		return
+	}
 
 	if !hasUpdate {
 		fmt.Printf("Your %s is up-to-date. \\o/\n", info.AppName)
@@ -86,12 +175,14 @@ func (c *Update) Execute(info *infos) {
 	}
 
 	var (
-		assetToDownload grc.Asset
+		assetToDownload Asset
 		found           bool
 	)
 
+	matches := c.assetMatcher()
+
 	for _, asset := range release.Assets {
-		if asset.Name == c.buildFilename(release.TagName, info) {
+		if matches(asset.Name) {
 			assetToDownload = asset
 			found = true
 
@@ -105,7 +196,7 @@ func (c *Update) Execute(info *infos) {
 		return
 	}
 
-	downloadError := c.downloadBinary(assetToDownload.BrowserDownloadURL, info)
+	downloadError := c.downloadBinary(ctx, fetcher, release.Assets, assetToDownload, release.TagName, info)
 	if downloadError != nil {
 		fmt.Printf("Error: %s\n", downloadError.Error())
 	}
@@ -113,37 +204,54 @@ func (c *Update) Execute(info *infos) {
 	fmt.Printf("Now you have a fresh new %s \\o/\n", info.AppName)
 }
 
-func (c *Update) buildFilename(version string, info *infos) string {
-	return fmt.Sprintf("%s-%s-%s-%s.tar.gz", info.AppName, version, runtime.GOOS, runtime.GOARCH)
-}
-
-func (c *Update) downloadBinary(uri string, info *infos) error {
+func (c *Update) downloadBinary(ctx context.Context, fetcher Fetcher, assets []Asset, asset Asset, version string, info *infos) error {
 	fmt.Println(" -> Download...")
 
-	client := http.Client{}
+	expectedDigest, err := fetchExpectedChecksum(ctx, fetcher, assets, version, info, asset.Name)
+	if err != nil {
+		return err
+	}
+
+	if expectedDigest == "" && c.RequireChecksums {
+		return ChecksumError{Message: fmt.Sprintf("no checksums.txt published for release %s", version)}
+	}
 
-	request, err := http.NewRequestWithContext(context.Background(), http.MethodGet, uri, nil)
+	body, err := fetcher.Download(ctx, asset)
 	if err != nil {
-		return DownloadError{Message: err.Error()}
+		return err
 	}
 
-	response, err := client.Do(request)
+	defer body.Close()
+
+	hasher := sha256Hasher()
+
+	payload, err := io.ReadAll(io.TeeReader(body, hasher))
 	if err != nil {
 		return DownloadError{Message: err.Error()}
 	}
 
-	defer response.Body.Close()
+	if expectedDigest != "" {
+		if err := verifyDigest(hasher, expectedDigest); err != nil {
+			return err
+		}
+	}
 
-	gzipReader, _ := gzip.NewReader(response.Body)
-	defer gzipReader.Close()
+	if c.ExpectedSigner != nil {
+		sigAsset, ok := fetchSignatureAsset(assets, asset.Name)
+		if !ok {
+			return SignatureError{Message: fmt.Sprintf("no signature published for %s", asset.Name)}
+		}
 
-	fmt.Println(" -> Extract...")
+		if err := verifySignature(ctx, fetcher, sigAsset, c.ExpectedSigner, payload); err != nil {
+			return err
+		}
+	}
 
-	tarReader := tar.NewReader(gzipReader)
+	fmt.Println(" -> Extract...")
 
-	_, err = tarReader.Next()
+	binaryReader, err := extractBinary(bytes.NewReader(payload), info.AppName)
 	if err != nil {
-		return DownloadError{Message: err.Error()}
+		return err
 	}
 
 	currentExecutable, _ := osext.Executable()
@@ -154,23 +262,32 @@ func (c *Update) downloadBinary(uri string, info *infos) error {
 		return DownloadError{Message: err.Error()}
 	}
 
-	defer file.Close()
-
-	_, err = io.Copy(file, tarReader) //nolint:gosec // I don't have better option right now.
+	_, err = io.Copy(file, binaryReader) //nolint:gosec // I don't have better option right now.
 	if err != nil {
+		file.Close()
+
 		return DownloadError{Message: err.Error()}
 	}
 
 	err = file.Chmod(binaryChmodValue)
 	if err != nil {
+		file.Close()
+
 		return DownloadError{Message: err.Error()}
 	}
 
-	err = os.Rename(file.Name(), currentExecutable)
-	if err != nil {
+	if err := file.Close(); err != nil {
 		return DownloadError{Message: err.Error()}
 	}
 
+	if err := replaceExecutable(file.Name(), currentExecutable); err != nil {
+		return err
+	}
+
+	if c.Restart {
+		return restartExecutable(currentExecutable, os.Args)
+	}
+
 	return nil
 }
 
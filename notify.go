@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// defaultCheckInterval is how often PromptForUpdate is willing to hit the
+// network, reusing the cached state file in between.
+const defaultCheckInterval = 24 * time.Hour
+
+const stateFilePerm = 0o644
+
+// updateState is persisted to <UserCacheDir>/<AppName>/update.json between
+// runs so PromptForUpdate doesn't call out to the network on every
+// invocation of the embedding CLI.
+type updateState struct {
+	LastChecked time.Time `json:"last_checked"`
+	LastSeen    string    `json:"last_seen"`
+}
+
+// checkInterval returns c.CheckInterval, or defaultCheckInterval if unset.
+func (c *Update) checkInterval() time.Duration {
+	if c.CheckInterval > 0 {
+		return c.CheckInterval
+	}
+
+	return defaultCheckInterval
+}
+
+// statePath returns the path to this app's cached update-check state.
+func statePath(info *infos) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(cacheDir, info.AppName, "update.json"), nil
+}
+
+func readState(path string) (updateState, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return updateState{}, false
+	}
+
+	var state updateState
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return updateState{}, false
+	}
+
+	return state, true
+}
+
+func writeState(path string, state updateState) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, stateFilePerm)
+}
+
+// skipEnvVar returns the env var name that opts an app out of update
+// checks, e.g. "MYAPP_SKIP_UPDATE_CHECK".
+func skipEnvVar(info *infos) string {
+	return strings.ToUpper(info.AppName) + "_SKIP_UPDATE_CHECK"
+}
+
+// CheckForUpdate reports whether a release newer than info.AppVersion is
+// available, unconditionally querying c.fetcher(info) (c.Fetcher, or the
+// default GitHubFetcher when that is nil). PromptForUpdate wraps
+// this with rate limiting for interactive/background use.
+func (c *Update) CheckForUpdate(ctx context.Context, info *infos) (available bool, latest string, err error) {
+	release, hasUpdate, err := c.fetcher(info).LatestRelease(ctx, info.AppVersion)
+	if err != nil {
+		return false, "", err
+	}
+
+	return hasUpdate, release.TagName, nil
+}
+
+// PromptForUpdate prints a one-line notice to stderr when a newer release
+// is available. It is meant to be called unconditionally at the top of a
+// CLI's own commands: the network check is skipped (reusing the last
+// cached result) unless c.checkInterval() has elapsed since the last
+// successful check, and it's skipped entirely when <APPNAME>_SKIP_UPDATE_CHECK=1 is
+// set in the environment.
+func (c *Update) PromptForUpdate(ctx context.Context, info *infos) {
+	if os.Getenv(skipEnvVar(info)) == "1" {
+		return
+	}
+
+	path, err := statePath(info)
+	if err != nil {
+		return
+	}
+
+	if state, ok := readState(path); ok && time.Since(state.LastChecked) < c.checkInterval() {
+		c.printUpdateNotice(info, state.LastSeen)
+
+		return
+	}
+
+	hasUpdate, latest, err := c.CheckForUpdate(ctx, info)
+	if err != nil {
+		return
+	}
+
+	state := updateState{LastChecked: time.Now(), LastSeen: info.AppVersion}
+	if hasUpdate {
+		state.LastSeen = latest
+	}
+
+	_ = writeState(path, state)
+
+	if hasUpdate {
+		c.printUpdateNotice(info, latest)
+	}
+}
+
+func (c *Update) printUpdateNotice(info *infos, latest string) {
+	if latest == "" || latest == info.AppVersion {
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "%s available, run `%s update` to install\n", latest, info.AppName)
+}
@@ -0,0 +1,13 @@
+package main
+
+import "fmt"
+
+// ReplaceError is an error encountered while swapping the running
+// executable for a newly downloaded one.
+type ReplaceError struct {
+	Message string
+}
+
+func (e ReplaceError) Error() string {
+	return fmt.Sprintf("replace error: %s", e.Message)
+}
@@ -0,0 +1,34 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// replaceExecutable atomically swaps currentExecutable for the contents
+// already written to newBinary, relying on rename(2) being atomic when
+// both paths are on the same filesystem (downloadBinary creates newBinary
+// alongside currentExecutable for exactly this reason).
+func replaceExecutable(newBinary, currentExecutable string) error {
+	if err := os.Rename(newBinary, currentExecutable); err != nil {
+		return ReplaceError{Message: err.Error()}
+	}
+
+	return nil
+}
+
+// restartExecutable replaces the current process image with
+// currentExecutable, so on success this call never returns.
+func restartExecutable(currentExecutable string, args []string) error {
+	if err := syscall.Exec(currentExecutable, args, os.Environ()); err != nil {
+		return ReplaceError{Message: err.Error()}
+	}
+
+	return nil
+}
+
+// cleanupStaleReplace is a no-op on platforms where replaceExecutable
+// never has to leave a ".old" backup behind.
+func cleanupStaleReplace(_ string) {}
@@ -0,0 +1,64 @@
+//go:build windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// oldSuffix names the backup left behind when the running executable has
+// to be moved out of the way before it can be replaced.
+const oldSuffix = ".old"
+
+// replaceExecutable swaps currentExecutable for newBinary on Windows,
+// where the running executable's file is locked and can't be overwritten
+// directly. The running binary is renamed to <name>.old (permitted while
+// it's running), the new binary is moved into its place, and the backup
+// is restored if that second rename fails.
+func replaceExecutable(newBinary, currentExecutable string) error {
+	oldPath := currentExecutable + oldSuffix
+
+	_ = os.Remove(oldPath) // leftover .old from an update that never restarted; best effort.
+
+	if err := os.Rename(currentExecutable, oldPath); err != nil {
+		return ReplaceError{Message: err.Error()}
+	}
+
+	if err := os.Rename(newBinary, currentExecutable); err != nil {
+		if restoreErr := os.Rename(oldPath, currentExecutable); restoreErr != nil {
+			return ReplaceError{Message: fmt.Sprintf("%s (rollback also failed: %s)", err, restoreErr)}
+		}
+
+		return ReplaceError{Message: err.Error()}
+	}
+
+	// The old binary may still be locked by this process; removal is
+	// best-effort and retried by cleanupStaleReplace on next start.
+	_ = os.Remove(oldPath)
+
+	return nil
+}
+
+// restartExecutable starts a new instance of currentExecutable and exits
+// this process, since Windows can't exec over a running process image.
+func restartExecutable(currentExecutable string, args []string) error {
+	process, err := os.StartProcess(currentExecutable, args, &os.ProcAttr{
+		Files: []*os.File{os.Stdin, os.Stdout, os.Stderr},
+	})
+	if err != nil {
+		return ReplaceError{Message: err.Error()}
+	}
+
+	_ = process.Release()
+
+	os.Exit(0)
+
+	return nil
+}
+
+// cleanupStaleReplace removes a leftover ".old" backup from a previous
+// replaceExecutable call, now that it's no longer locked.
+func cleanupStaleReplace(currentExecutable string) {
+	_ = os.Remove(currentExecutable + oldSuffix)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// Channel selects which class of GitHub releases a GitHubFetcher will
+// consider when looking for the latest version.
+type Channel string
+
+const (
+	// ChannelStable skips any release flagged as a GitHub prerelease or
+	// whose tag carries a semver prerelease suffix (e.g. "-rc.1").
+	ChannelStable Channel = "stable"
+
+	// ChannelBeta accepts releases tagged "-beta" or "-rc" in addition
+	// to stable ones.
+	ChannelBeta Channel = "beta"
+
+	// ChannelNightly accepts the newest release regardless of its
+	// prerelease flag or tag suffix.
+	ChannelNightly Channel = "nightly"
+)
+
+// canonicalTag prefixes tag with "v" if it's missing one, since
+// golang.org/x/mod/semver requires the leading "v" that release tags
+// don't always carry.
+func canonicalTag(tag string) string {
+	if tag == "" || tag[0] == 'v' {
+		return tag
+	}
+
+	return "v" + tag
+}
+
+// matchesChannel reports whether a release belongs to channel, given its
+// tag and GitHub's own "prerelease" flag.
+func matchesChannel(channel Channel, tag string, prerelease bool) bool {
+	pre := strings.TrimPrefix(semver.Prerelease(canonicalTag(tag)), "-")
+
+	switch channel {
+	case ChannelNightly:
+		return true
+	case ChannelBeta:
+		return (!prerelease && pre == "") || strings.HasPrefix(pre, "beta") || strings.HasPrefix(pre, "rc")
+	default: // ChannelStable, and the zero value, both mean "stable only".
+		return !prerelease && pre == ""
+	}
+}
+
+// pickRelease selects the release a GitHubFetcher should treat as
+// "latest" out of releases (as returned by the GitHub API, newest
+// first), honoring pin over channel filtering when set.
+func pickRelease(releases []githubRelease, channel Channel, pin string) (githubRelease, bool) {
+	if pin != "" {
+		for _, release := range releases {
+			if canonicalTag(release.TagName) == canonicalTag(pin) {
+				return release, true
+			}
+		}
+
+		return githubRelease{}, false
+	}
+
+	var (
+		best    githubRelease
+		bestTag string
+		found   bool
+	)
+
+	for _, release := range releases {
+		if !matchesChannel(channel, release.TagName, release.Prerelease) {
+			continue
+		}
+
+		if channel == ChannelNightly {
+			return release, true // GitHub returns releases newest-first.
+		}
+
+		tag := canonicalTag(release.TagName)
+		if !found || semver.Compare(tag, bestTag) > 0 {
+			best, bestTag, found = release, tag, true
+		}
+	}
+
+	return best, found
+}
+
+// versionNewer reports whether candidate should be installed over
+// current: strictly newer by semver, or any different version at all
+// when allowDowngrade permits rolling back.
+func versionNewer(candidate, current string, allowDowngrade bool) bool {
+	if current == "" {
+		return true
+	}
+
+	cmp := semver.Compare(canonicalTag(candidate), canonicalTag(current))
+	if allowDowngrade {
+		return cmp != 0
+	}
+
+	return cmp > 0
+}
+
+// resolveUpdate applies Pin/AllowDowngrade policy uniformly for fetchers
+// that can only ever see a single "latest" candidate (GitLab, S3, plain
+// HTTP manifests), unlike GitHubFetcher's pickRelease, which can search
+// a whole release list for a pinned tag. If pin is set and candidate
+// doesn't match it, there's no way for these fetchers to find the
+// pinned release, so no update is reported. Otherwise candidate is
+// compared against current via versionNewer.
+func resolveUpdate(candidate, current, pin string, allowDowngrade bool) bool {
+	if pin != "" && canonicalTag(candidate) != canonicalTag(pin) {
+		return false
+	}
+
+	return versionNewer(candidate, current, allowDowngrade)
+}
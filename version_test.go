@@ -0,0 +1,117 @@
+package main
+
+import "testing"
+
+func TestMatchesChannel(t *testing.T) {
+	tests := []struct {
+		name       string
+		channel    Channel
+		tag        string
+		prerelease bool
+		want       bool
+	}{
+		{name: "stable accepts plain release", channel: ChannelStable, tag: "v1.2.3", want: true},
+		{name: "stable rejects github prerelease flag", channel: ChannelStable, tag: "v1.2.3", prerelease: true, want: false},
+		{name: "stable rejects beta suffix", channel: ChannelStable, tag: "v1.2.3-beta.1", want: false},
+		{name: "beta accepts plain release", channel: ChannelBeta, tag: "v1.2.3", want: true},
+		{name: "beta accepts beta suffix", channel: ChannelBeta, tag: "v1.2.3-beta.1", want: true},
+		{name: "beta accepts rc suffix", channel: ChannelBeta, tag: "v1.2.3-rc.1", want: true},
+		{name: "beta rejects unrelated prerelease suffix", channel: ChannelBeta, tag: "v1.2.3-nightly.20240101", want: false},
+		{name: "nightly accepts anything", channel: ChannelNightly, tag: "v1.2.3-nightly.20240101", want: true},
+		{name: "tag without leading v is canonicalized", channel: ChannelBeta, tag: "1.2.3-beta.1", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesChannel(tt.channel, tt.tag, tt.prerelease); got != tt.want {
+				t.Fatalf("matchesChannel(%v, %q, %v) = %v, want %v", tt.channel, tt.tag, tt.prerelease, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPickRelease(t *testing.T) {
+	releases := []githubRelease{
+		{TagName: "v2.0.0-beta.1"},
+		{TagName: "v1.5.0"},
+		{TagName: "v1.4.0"},
+	}
+
+	t.Run("stable skips prerelease and picks newest", func(t *testing.T) {
+		got, found := pickRelease(releases, ChannelStable, "")
+		if !found || got.TagName != "v1.5.0" {
+			t.Fatalf("got %+v, found=%v, want v1.5.0", got, found)
+		}
+	})
+
+	t.Run("beta picks newest including prerelease", func(t *testing.T) {
+		got, found := pickRelease(releases, ChannelBeta, "")
+		if !found || got.TagName != "v2.0.0-beta.1" {
+			t.Fatalf("got %+v, found=%v, want v2.0.0-beta.1", got, found)
+		}
+	})
+
+	t.Run("pin overrides channel filtering", func(t *testing.T) {
+		got, found := pickRelease(releases, ChannelStable, "v2.0.0-beta.1")
+		if !found || got.TagName != "v2.0.0-beta.1" {
+			t.Fatalf("got %+v, found=%v, want pinned v2.0.0-beta.1", got, found)
+		}
+	})
+
+	t.Run("pin with no matching tag is not found", func(t *testing.T) {
+		_, found := pickRelease(releases, ChannelStable, "v9.9.9")
+		if found {
+			t.Fatalf("expected no release to match an unknown pin")
+		}
+	})
+}
+
+func TestVersionNewer(t *testing.T) {
+	tests := []struct {
+		name           string
+		candidate      string
+		current        string
+		allowDowngrade bool
+		want           bool
+	}{
+		{name: "newer candidate", candidate: "v1.1.0", current: "v1.0.0", want: true},
+		{name: "older candidate rejected by default", candidate: "v1.0.0", current: "v1.1.0", want: false},
+		{name: "older candidate allowed with downgrade", candidate: "v1.0.0", current: "v1.1.0", allowDowngrade: true, want: true},
+		{name: "equal candidate rejected by default", candidate: "v1.0.0", current: "v1.0.0", want: false},
+		{name: "equal candidate rejected even with downgrade", candidate: "v1.0.0", current: "v1.0.0", allowDowngrade: true, want: false},
+		{name: "empty current always updates", candidate: "v1.0.0", current: "", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := versionNewer(tt.candidate, tt.current, tt.allowDowngrade); got != tt.want {
+				t.Fatalf("versionNewer(%q, %q, %v) = %v, want %v", tt.candidate, tt.current, tt.allowDowngrade, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveUpdate(t *testing.T) {
+	tests := []struct {
+		name           string
+		candidate      string
+		current        string
+		pin            string
+		allowDowngrade bool
+		want           bool
+	}{
+		{name: "no pin, newer candidate updates", candidate: "v1.1.0", current: "v1.0.0", want: true},
+		{name: "pin matching candidate updates", candidate: "v1.1.0", current: "v1.0.0", pin: "v1.1.0", want: true},
+		{name: "pin not matching the only candidate never updates", candidate: "v1.1.0", current: "v1.0.0", pin: "v2.0.0", want: false},
+		{name: "pin matching an older candidate needs allowDowngrade", candidate: "v1.0.0", current: "v1.1.0", pin: "v1.0.0", want: false},
+		{name: "pin matching an older candidate with allowDowngrade updates", candidate: "v1.0.0", current: "v1.1.0", pin: "v1.0.0", allowDowngrade: true, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveUpdate(tt.candidate, tt.current, tt.pin, tt.allowDowngrade); got != tt.want {
+				t.Fatalf("resolveUpdate(%q, %q, %q, %v) = %v, want %v", tt.candidate, tt.current, tt.pin, tt.allowDowngrade, got, tt.want)
+			}
+		})
+	}
+}